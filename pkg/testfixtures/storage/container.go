@@ -0,0 +1,24 @@
+package storage
+
+import "context"
+
+// DatastoreTestContainer is implemented by datastore-engine-specific test containers (e.g. MySQL,
+// Postgres) so that integration tests can be written generically against whichever engine is
+// under test. It mirrors the shape of the containers vended by testcontainers-go's own database
+// modules.
+type DatastoreTestContainer interface {
+	// ConnectionString returns a connection string to an isolated database provisioned for the
+	// calling test. Each container handed out for a test has its own database (repeated calls on
+	// it return the same one), so tests sharing the same underlying container do not observe each
+	// other's state.
+	ConnectionString(ctx context.Context) (string, error)
+
+	// Host returns the host that the datastore container is reachable on.
+	Host(ctx context.Context) (string, error)
+
+	// Port returns the mapped port that the datastore container is reachable on.
+	Port(ctx context.Context) (string, error)
+
+	// Terminate stops and removes the underlying container.
+	Terminate(ctx context.Context) error
+}
@@ -0,0 +1,200 @@
+// Package fixtures lets integration tests declare their datastore pre-state declaratively,
+// instead of hand-writing WriteAuthorizationModel/Write/WriteAssertions calls. A fixture is a
+// directory containing a "model" file (the authorization model), an optional "tuples" file, and
+// an optional "assertions" file, each as JSON or YAML.
+package fixtures
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"gopkg.in/yaml.v3"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/openfga/openfga/pkg/id"
+	"github.com/openfga/openfga/pkg/storage"
+)
+
+const (
+	modelFileStem      = "model"
+	tuplesFileStem     = "tuples"
+	assertionsFileStem = "assertions"
+)
+
+// errFixtureNotFound is returned by readFixtureFile when neither a .json nor a .yaml/.yml file
+// exists for the requested stem. Load uses it to tell "this optional fixture file is absent"
+// apart from "this fixture file exists but failed to parse."
+var errFixtureNotFound = errors.New("fixture file not found")
+
+// tupleFixture mirrors the shape of a single tuple entry in a fixture's tuples file.
+type tupleFixture struct {
+	User     string `json:"user" yaml:"user"`
+	Relation string `json:"relation" yaml:"relation"`
+	Object   string `json:"object" yaml:"object"`
+}
+
+// assertionFixture mirrors the shape of a single assertion entry in a fixture's assertions file.
+type assertionFixture struct {
+	TupleKey    tupleFixture `json:"tuple_key" yaml:"tuple_key"`
+	Expectation bool         `json:"expectation" yaml:"expectation"`
+}
+
+// Load reads the fixture at dir within fsys, writes its authorization model, tuples, and
+// assertions into ds under a freshly generated store and model ID, and returns both IDs.
+// Subsequent calls against ds (e.g. Check, ReadAuthorizationModel) should be scoped to them.
+func Load(ctx context.Context, ds storage.OpenFGADatastore, fsys fs.FS, dir string) (storeID, modelID string, err error) {
+	storeID, err = id.NewString()
+	if err != nil {
+		return "", "", fmt.Errorf("generating store id: %w", err)
+	}
+
+	modelID, err = id.NewString()
+	if err != nil {
+		return "", "", fmt.Errorf("generating model id: %w", err)
+	}
+
+	modelJSON, err := readFixtureFile(fsys, dir, modelFileStem)
+	if err != nil {
+		return "", "", fmt.Errorf("reading model fixture: %w", err)
+	}
+
+	model := &openfgav1.AuthorizationModel{}
+	if err := protojson.Unmarshal(modelJSON, model); err != nil {
+		return "", "", fmt.Errorf("parsing model fixture: %w", err)
+	}
+
+	model.Id = modelID
+
+	if err := ds.WriteAuthorizationModel(ctx, storeID, model); err != nil {
+		return "", "", fmt.Errorf("writing authorization model: %w", err)
+	}
+
+	tupleKeys, err := loadTuples(fsys, dir)
+	if err != nil {
+		return "", "", err
+	}
+
+	if len(tupleKeys) > 0 {
+		if err := ds.Write(ctx, storeID, nil, &openfgav1.TupleKeys{TupleKeys: tupleKeys}); err != nil {
+			return "", "", fmt.Errorf("writing tuples: %w", err)
+		}
+	}
+
+	assertions, err := loadAssertions(fsys, dir)
+	if err != nil {
+		return "", "", err
+	}
+
+	if len(assertions) > 0 {
+		if err := ds.WriteAssertions(ctx, storeID, modelID, assertions); err != nil {
+			return "", "", fmt.Errorf("writing assertions: %w", err)
+		}
+	}
+
+	return storeID, modelID, nil
+}
+
+// MustLoad is Load for tests: it loads the fixture at dir (relative to the test package, e.g.
+// "testdata/github") into ds and fails the test on any error.
+func MustLoad(t testing.TB, ds storage.OpenFGADatastore, dir string) (storeID, modelID string) {
+	t.Helper()
+
+	storeID, modelID, err := Load(context.Background(), ds, os.DirFS("."), dir)
+	require.NoError(t, err)
+
+	return storeID, modelID
+}
+
+// loadTuples reads dir's optional tuples fixture. A missing file is not an error; a malformed
+// one is.
+func loadTuples(fsys fs.FS, dir string) ([]*openfgav1.TupleKey, error) {
+	b, err := readFixtureFile(fsys, dir, tuplesFileStem)
+	if err != nil {
+		if errors.Is(err, errFixtureNotFound) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("reading tuples fixture: %w", err)
+	}
+
+	var rawTuples []tupleFixture
+	if err := json.Unmarshal(b, &rawTuples); err != nil {
+		return nil, fmt.Errorf("parsing tuples fixture: %w", err)
+	}
+
+	tupleKeys := make([]*openfgav1.TupleKey, 0, len(rawTuples))
+	for _, tk := range rawTuples {
+		tupleKeys = append(tupleKeys, &openfgav1.TupleKey{
+			User:     tk.User,
+			Relation: tk.Relation,
+			Object:   tk.Object,
+		})
+	}
+
+	return tupleKeys, nil
+}
+
+// loadAssertions reads dir's optional assertions fixture. A missing file is not an error; a
+// malformed one is.
+func loadAssertions(fsys fs.FS, dir string) ([]*openfgav1.Assertion, error) {
+	b, err := readFixtureFile(fsys, dir, assertionsFileStem)
+	if err != nil {
+		if errors.Is(err, errFixtureNotFound) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("reading assertions fixture: %w", err)
+	}
+
+	var rawAssertions []assertionFixture
+	if err := json.Unmarshal(b, &rawAssertions); err != nil {
+		return nil, fmt.Errorf("parsing assertions fixture: %w", err)
+	}
+
+	assertions := make([]*openfgav1.Assertion, 0, len(rawAssertions))
+	for _, a := range rawAssertions {
+		assertions = append(assertions, &openfgav1.Assertion{
+			TupleKey: &openfgav1.TupleKey{
+				User:     a.TupleKey.User,
+				Relation: a.TupleKey.Relation,
+				Object:   a.TupleKey.Object,
+			},
+			Expectation: a.Expectation,
+		})
+	}
+
+	return assertions, nil
+}
+
+// readFixtureFile reads dir/stem.json, dir/stem.yaml or dir/stem.yml (in that order) and returns
+// its contents as JSON, converting from YAML if necessary. It returns errFixtureNotFound if none
+// of them exists.
+func readFixtureFile(fsys fs.FS, dir, stem string) ([]byte, error) {
+	if b, err := fs.ReadFile(fsys, path.Join(dir, stem+".json")); err == nil {
+		return b, nil
+	}
+
+	for _, ext := range []string{".yaml", ".yml"} {
+		b, err := fs.ReadFile(fsys, path.Join(dir, stem+ext))
+		if err != nil {
+			continue
+		}
+
+		var doc interface{}
+		if err := yaml.Unmarshal(b, &doc); err != nil {
+			return nil, fmt.Errorf("parsing %s%s: %w", stem, ext, err)
+		}
+
+		return json.Marshal(doc)
+	}
+
+	return nil, fmt.Errorf("%w: no %s.json or %s.yaml in %s", errFixtureNotFound, stem, stem, dir)
+}
@@ -0,0 +1,135 @@
+package fixtures
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/openfga/openfga/pkg/storage"
+)
+
+// fakeDatastore records what Load writes to it. Embedding the (nil) storage.OpenFGADatastore
+// interface satisfies the rest of that large interface; only the methods Load actually calls are
+// overridden below, so calling anything else would panic rather than silently no-op.
+type fakeDatastore struct {
+	storage.OpenFGADatastore
+
+	model      *openfgav1.AuthorizationModel
+	tuples     []*openfgav1.TupleKey
+	assertions []*openfgav1.Assertion
+}
+
+func (f *fakeDatastore) WriteAuthorizationModel(_ context.Context, _ string, model *openfgav1.AuthorizationModel) error {
+	f.model = model
+	return nil
+}
+
+func (f *fakeDatastore) Write(_ context.Context, _ string, _ *openfgav1.TupleKeys, writes *openfgav1.TupleKeys) error {
+	if writes != nil {
+		f.tuples = append(f.tuples, writes.GetTupleKeys()...)
+	}
+
+	return nil
+}
+
+func (f *fakeDatastore) WriteAssertions(_ context.Context, _ string, _ string, assertions []*openfgav1.Assertion) error {
+	f.assertions = assertions
+	return nil
+}
+
+const validModelJSON = `{"schema_version":"1.1","type_definitions":[{"type":"document"}]}`
+
+func TestReadFixtureFile(t *testing.T) {
+	t.Run("prefers json over yaml", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"f/model.json": {Data: []byte(`{"schema_version":"1.1"}`)},
+			"f/model.yaml": {Data: []byte("schema_version: \"9.9\"")},
+		}
+
+		b, err := readFixtureFile(fsys, "f", "model")
+		require.NoError(t, err)
+		require.JSONEq(t, `{"schema_version":"1.1"}`, string(b))
+	})
+
+	t.Run("falls back to yaml", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"f/model.yaml": {Data: []byte("schema_version: \"1.1\"")},
+		}
+
+		b, err := readFixtureFile(fsys, "f", "model")
+		require.NoError(t, err)
+		require.JSONEq(t, `{"schema_version":"1.1"}`, string(b))
+	})
+
+	t.Run("missing file is errFixtureNotFound", func(t *testing.T) {
+		fsys := fstest.MapFS{}
+
+		_, err := readFixtureFile(fsys, "f", "model")
+		require.ErrorIs(t, err, errFixtureNotFound)
+	})
+
+	t.Run("malformed yaml is a parse error, not errFixtureNotFound", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"f/model.yaml": {Data: []byte("not: [valid: yaml")},
+		}
+
+		_, err := readFixtureFile(fsys, "f", "model")
+		require.Error(t, err)
+		require.NotErrorIs(t, err, errFixtureNotFound)
+	})
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("malformed tuples fixture fails loudly instead of being skipped as optional", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"f/model.json":  {Data: []byte(validModelJSON)},
+			"f/tuples.json": {Data: []byte("not valid json")},
+		}
+
+		ds := &fakeDatastore{}
+
+		_, _, err := Load(context.Background(), ds, fsys, "f")
+		require.Error(t, err)
+	})
+
+	t.Run("loads model, tuples, and assertions", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"f/model.json": {Data: []byte(validModelJSON)},
+			"f/tuples.json": {Data: []byte(`[{"user":"user:anne","relation":"viewer","object":"document:1"}]`)},
+			"f/assertions.json": {Data: []byte(`[{"tuple_key":{"user":"user:anne","relation":"viewer","object":"document:1"},"expectation":true}]`)},
+		}
+
+		ds := &fakeDatastore{}
+
+		storeID, modelID, err := Load(context.Background(), ds, fsys, "f")
+		require.NoError(t, err)
+		require.NotEmpty(t, storeID)
+		require.NotEmpty(t, modelID)
+
+		require.NotNil(t, ds.model)
+		require.Equal(t, modelID, ds.model.GetId())
+		require.Len(t, ds.tuples, 1)
+		require.Equal(t, "user:anne", ds.tuples[0].GetUser())
+
+		require.Len(t, ds.assertions, 1)
+		require.True(t, ds.assertions[0].GetExpectation())
+	})
+
+	t.Run("tuples and assertions are optional", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"f/model.json": {Data: []byte(validModelJSON)},
+		}
+
+		ds := &fakeDatastore{}
+
+		storeID, modelID, err := Load(context.Background(), ds, fsys, "f")
+		require.NoError(t, err)
+		require.NotEmpty(t, storeID)
+		require.NotEmpty(t, modelID)
+		require.Empty(t, ds.tuples)
+		require.Empty(t, ds.assertions)
+	})
+}
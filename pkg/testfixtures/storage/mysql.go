@@ -4,31 +4,89 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"io"
 	"testing"
-	"time"
 
-	"github.com/cenkalti/backoff/v4"
-	"github.com/docker/docker/api/types"
-	"github.com/docker/docker/api/types/container"
-	"github.com/docker/docker/client"
-	"github.com/docker/go-connections/nat"
 	_ "github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/mysql"
 
 	"github.com/openfga/openfga/assets"
 	"github.com/openfga/openfga/pkg/id"
 	"github.com/pressly/goose/v3"
-	"github.com/stretchr/testify/require"
 )
 
 const (
-	mySQLImage = "mysql:latest"
+	defaultMySQLImage = "mysql"
+	defaultMySQLTag   = "8.0"
 )
 
+// mySQLContainerConfig holds the configuration applied by MySQLTestContainerOpt to a MySQL test
+// container before it's started.
+type mySQLContainerConfig struct {
+	image        string
+	tag          string
+	platform     string
+	initScripts  []string
+	rootPassword string
+	database     string
+	cmdArgs      []string
+}
+
+// MySQLTestContainerOpt configures the MySQL container started by RunMySQLTestContainer.
+type MySQLTestContainerOpt func(*mySQLContainerConfig)
+
+// WithImage overrides the default MySQL-compatible image (e.g. "mariadb").
+func WithImage(image string) MySQLTestContainerOpt {
+	return func(c *mySQLContainerConfig) { c.image = image }
+}
+
+// WithTag overrides the default image tag (e.g. "5.7").
+func WithTag(tag string) MySQLTestContainerOpt {
+	return func(c *mySQLContainerConfig) { c.tag = tag }
+}
+
+// WithPlatform pins the image platform to pull (e.g. "linux/amd64"), needed for images that
+// don't publish an arm64 build.
+func WithPlatform(platform string) MySQLTestContainerOpt {
+	return func(c *mySQLContainerConfig) { c.platform = platform }
+}
+
+// WithInitScripts runs the given SQL script files against the bootstrap database (see
+// WithDatabase) when the server first starts. The per-test databases handed out by
+// RunMySQLTestContainer are created afterwards and do not contain what the scripts create there.
+func WithInitScripts(scripts ...string) MySQLTestContainerOpt {
+	return func(c *mySQLContainerConfig) { c.initScripts = scripts }
+}
+
+// WithRootPassword overrides the default root password.
+func WithRootPassword(password string) MySQLTestContainerOpt {
+	return func(c *mySQLContainerConfig) { c.rootPassword = password }
+}
+
+// WithDatabase overrides the default bootstrap database created on the server.
+func WithDatabase(database string) MySQLTestContainerOpt {
+	return func(c *mySQLContainerConfig) { c.database = database }
+}
+
+// WithCmdArgs passes extra flags to the `mysqld` server process, e.g. "--max-connections=500".
+func WithCmdArgs(args ...string) MySQLTestContainerOpt {
+	return func(c *mySQLContainerConfig) { c.cmdArgs = args }
+}
+
+// mySQLTestContainer implements DatastoreTestContainer for MySQL. It wraps a MySQL container
+// that may be shared across many tests, and hands out an isolated database per test so that
+// tests running against the shared container can't see each other's data.
 type mySQLTestContainer struct {
-	conn  *sql.DB
-	addr  string
-	creds string
+	container *mysql.MySQLContainer
+	rootCreds string
+	database  string
+
+	// root is true only for the single wrapper stored in the package's shared container
+	// registry. Per-test wrappers returned to callers have root == false, so their Terminate is
+	// a no-op instead of tearing down the container every other test in the package is using;
+	// TerminateSharedContainers is the only supported way to tear down the real container.
+	root bool
 }
 
 // NewMySQLTestContainer returns an implementation of the DatastoreTestContainer interface
@@ -37,120 +95,103 @@ func NewMySQLTestContainer() *mySQLTestContainer {
 	return &mySQLTestContainer{}
 }
 
-// RunMySQLTestContainer runs a MySQL container, connects to it, and returns a
-// bootstrapped implementation of the DatastoreTestContainer interface wired up for the
-// MySQL datastore engine.
-func (m *mySQLTestContainer) RunMySQLTestContainer(t testing.TB) DatastoreTestContainer {
-	dockerClient, err := client.NewClientWithOpts(client.FromEnv)
-	require.NoError(t, err)
-
-	reader, err := dockerClient.ImagePull(context.Background(), mySQLImage, types.ImagePullOptions{})
-	require.NoError(t, err)
-
-	_, err = io.Copy(io.Discard, reader) // consume the image pull output to make sure it's done
-	require.NoError(t, err)
-
-	containerCfg := container.Config{
-		Env: []string{
-			"MYSQL_DATABASE=defaultdb",
-			"MYSQL_ROOT_PASSWORD=secret",
-		},
-		ExposedPorts: nat.PortSet{
-			nat.Port("3306/tcp"): {},
-		},
-		Image: mySQLImage,
+// RunMySQLTestContainer runs a MySQL container (or reuses one already running for this package
+// with the same configuration), provisions an isolated database on it, and returns a
+// bootstrapped implementation of the DatastoreTestContainer interface wired up for the MySQL
+// datastore engine.
+func (m *mySQLTestContainer) RunMySQLTestContainer(t testing.TB, opts ...MySQLTestContainerOpt) DatastoreTestContainer {
+	ctx := context.Background()
+
+	cfg := &mySQLContainerConfig{
+		image:        defaultMySQLImage,
+		tag:          defaultMySQLTag,
+		rootPassword: "secret",
+		database:     "defaultdb",
 	}
 
-	hostCfg := container.HostConfig{
-		AutoRemove:      true,
-		PublishAllPorts: false,
-		PortBindings: nat.PortMap{
-			"3306/tcp": []nat.PortBinding{
-				{HostPort: "3306"},
-			},
-		},
+	for _, opt := range opts {
+		opt(cfg)
 	}
 
-	ulid, err := id.NewString()
-	require.NoError(t, err)
-
-	name := fmt.Sprintf("mysql-%s", ulid)
+	discriminator := fmt.Sprintf("%s:%s@%s cmd=%v init=%v pw=%s db=%s", cfg.image, cfg.tag, cfg.platform, cfg.cmdArgs, cfg.initScripts, cfg.rootPassword, cfg.database)
 
-	cont, err := dockerClient.ContainerCreate(context.Background(), &containerCfg, &hostCfg, nil, nil, name)
-	require.NoError(t, err, "failed to create mysql docker container")
-
-	stopContainer := func() {
-
-		timeout := 5 * time.Second
+	shared, err := getOrCreateSharedContainer("mysql", discriminator, func(startCtx context.Context) (*sharedContainer, error) {
+		containerOpts := []testcontainers.ContainerCustomizer{
+			mysql.WithDatabase(cfg.database),
+			mysql.WithUsername("root"),
+			mysql.WithPassword(cfg.rootPassword),
+		}
 
-		err := dockerClient.ContainerStop(context.Background(), cont.ID, &timeout)
-		if err != nil && !client.IsErrNotFound(err) {
-			t.Fatalf("failed to stop mysql container: %v", err)
+		if len(cfg.initScripts) > 0 {
+			containerOpts = append(containerOpts, mysql.WithScripts(cfg.initScripts...))
 		}
-	}
 
-	err = dockerClient.ContainerStart(context.Background(), cont.ID, types.ContainerStartOptions{})
-	if err != nil {
-		stopContainer()
-		t.Fatalf("failed to start mysql container: %v", err)
-	}
+		if cfg.platform != "" {
+			containerOpts = append(containerOpts, testcontainers.WithImagePlatform(cfg.platform))
+		}
 
-	containerJSON, err := dockerClient.ContainerInspect(context.Background(), cont.ID)
-	require.NoError(t, err)
+		if len(cfg.cmdArgs) > 0 {
+			containerOpts = append(containerOpts, testcontainers.WithCmd(cfg.cmdArgs...))
+		}
 
-	p, ok := containerJSON.NetworkSettings.Ports["3306/tcp"]
-	if !ok || len(p) == 0 {
-		t.Fatalf("failed to get host port mapping from mysql container")
-	}
+		c, err := mysql.RunContainer(startCtx, append([]testcontainers.ContainerCustomizer{
+			testcontainers.WithImage(fmt.Sprintf("%s:%s", cfg.image, cfg.tag)),
+		}, containerOpts...)...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to start mysql container: %w", err)
+		}
 
-	// spin up a goroutine to survive any test panics to expire/stop the running container
-	go func() {
-		time.Sleep(expireTimeout)
+		host, err := c.Host(startCtx)
+		if err != nil {
+			return nil, err
+		}
 
-		err := dockerClient.ContainerStop(context.Background(), cont.ID, nil)
-		if err != nil && !client.IsErrNotFound(err) {
-			t.Fatalf("failed to expire mysql container: %v", err)
+		port, err := c.MappedPort(startCtx, "3306/tcp")
+		if err != nil {
+			return nil, err
 		}
-	}()
 
-	t.Cleanup(func() {
-		stopContainer()
+		return &sharedContainer{
+			container: &mySQLTestContainer{container: c, rootCreds: fmt.Sprintf("root:%s", cfg.rootPassword), root: true},
+			host:      host,
+			port:      port.Port(),
+		}, nil
 	})
+	require.NoError(t, err, "failed to get or create shared mysql container")
 
-	mySQLTestContainer := &mySQLTestContainer{
-		addr:  fmt.Sprintf("localhost:%s", p[0].HostPort),
-		creds: "root:secret",
-	}
+	ulid, err := id.NewString()
+	require.NoError(t, err)
 
-	uri := fmt.Sprintf("%s@tcp(%s)/defaultdb?parseTime=true", mySQLTestContainer.creds, mySQLTestContainer.addr)
+	database := fmt.Sprintf("openfga_test_%s", ulid)
 
-	backoffPolicy := backoff.NewExponentialBackOff()
-	backoffPolicy.MaxElapsedTime = 60 * time.Second
+	rootContainer := shared.container.(*mySQLTestContainer)
 
-	err = backoff.Retry(
-		func() error {
-			var err error
+	rootURI := fmt.Sprintf("%s@tcp(%s:%s)/", rootContainer.rootCreds, shared.host, shared.port)
 
-			mySQLTestContainer.conn, err = sql.Open("mysql", uri)
-			if err != nil {
-				return err
-			}
-			err = mySQLTestContainer.conn.Ping()
-			if err != nil {
-				return err
-			}
+	db, err := sql.Open("mysql", rootURI)
+	require.NoError(t, err)
+	defer db.Close()
 
-			return nil
-		},
-		backoffPolicy,
-	)
-	if err != nil {
-		stopContainer()
-		t.Fatalf("failed to connect to mysql container: %v", err)
+	_, err = db.ExecContext(ctx, fmt.Sprintf("CREATE DATABASE `%s`", database))
+	require.NoError(t, err, "failed to create isolated mysql test database")
+
+	t.Cleanup(func() {
+		conn, err := sql.Open("mysql", rootURI)
+		if err == nil {
+			_, _ = conn.ExecContext(context.Background(), fmt.Sprintf("DROP DATABASE IF EXISTS `%s`", database))
+			_ = conn.Close()
+		}
+	})
+
+	tc := &mySQLTestContainer{
+		container: rootContainer.container,
+		rootCreds: rootContainer.rootCreds,
+		database:  database,
 	}
 
-	db, err := sql.Open("mysql", uri)
+	migrateDB, err := sql.Open("mysql", fmt.Sprintf("%s@tcp(%s:%s)/%s?parseTime=true", tc.rootCreds, shared.host, shared.port, database))
 	require.NoError(t, err)
+	defer migrateDB.Close()
 
 	goose.SetLogger(goose.NopLogger())
 
@@ -159,18 +200,64 @@ func (m *mySQLTestContainer) RunMySQLTestContainer(t testing.TB) DatastoreTestCo
 
 	goose.SetBaseFS(assets.EmbedMigrations)
 
-	err = goose.Up(db, assets.MySQLMigrationDir)
+	err = goose.Up(migrateDB, assets.MySQLMigrationDir)
 	require.NoError(t, err)
 
-	return mySQLTestContainer
+	return tc
+}
+
+// ConnectionString returns the mysql connection uri for this test's isolated database.
+func (m *mySQLTestContainer) ConnectionString(ctx context.Context) (string, error) {
+	host, err := m.Host(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	port, err := m.Port(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s@tcp(%s:%s)/%s?parseTime=true", m.rootCreds, host, port, m.database), nil
+}
+
+// Host returns the host the underlying mysql container is reachable on.
+func (m *mySQLTestContainer) Host(ctx context.Context) (string, error) {
+	return m.container.Host(ctx)
+}
+
+// Port returns the mapped port the underlying mysql container is reachable on.
+func (m *mySQLTestContainer) Port(ctx context.Context) (string, error) {
+	port, err := m.container.MappedPort(ctx, "3306/tcp")
+	if err != nil {
+		return "", err
+	}
+
+	return port.Port(), nil
+}
+
+// Terminate stops and removes the underlying mysql container.
+//
+// Per-test containers returned by RunMySQLTestContainer share that container with every other
+// test in the package, so calling Terminate on one of them is a deliberate no-op rather than
+// tearing down the container out from under tests that are still running or haven't run yet. Use
+// TerminateSharedContainers (typically from a package's TestMain) to tear the container down.
+func (m *mySQLTestContainer) Terminate(ctx context.Context) error {
+	if !m.root {
+		return nil
+	}
+
+	return m.container.Terminate(ctx)
 }
 
 // GetConnectionURI returns the mysql connection uri for the running mysql test container.
+//
+// Deprecated: use ConnectionString instead.
 func (m *mySQLTestContainer) GetConnectionURI() string {
-	return fmt.Sprintf(
-		"%s@tcp(%s)/%s?parseTime=true",
-		m.creds,
-		m.addr,
-		"defaultdb",
-	)
+	uri, err := m.ConnectionString(context.Background())
+	if err != nil {
+		return ""
+	}
+
+	return uri
 }
@@ -0,0 +1,43 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestRunMySQLTestContainer_Parallel demonstrates that two tests requesting differently
+// configured MySQL containers get distinct containers on distinct, dynamically-allocated host
+// ports, so they can run concurrently under `go test -parallel`.
+func TestRunMySQLTestContainer_Parallel(t *testing.T) {
+	t.Parallel()
+
+	ports := make(chan string, 2)
+
+	t.Run("mysql-8", func(t *testing.T) {
+		t.Parallel()
+
+		c := NewMySQLTestContainer().RunMySQLTestContainer(t, WithTag("8.0"))
+
+		port, err := c.Port(context.Background())
+		require.NoError(t, err)
+
+		ports <- port
+	})
+
+	t.Run("mysql-5.7", func(t *testing.T) {
+		t.Parallel()
+
+		c := NewMySQLTestContainer().RunMySQLTestContainer(t, WithTag("5.7"))
+
+		port, err := c.Port(context.Background())
+		require.NoError(t, err)
+
+		ports <- port
+	})
+
+	t.Cleanup(func() {
+		assertDistinctPorts(t, "mysql", ports)
+	})
+}
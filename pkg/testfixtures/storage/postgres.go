@@ -0,0 +1,206 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+
+	"github.com/openfga/openfga/assets"
+	"github.com/openfga/openfga/pkg/id"
+	"github.com/pressly/goose/v3"
+)
+
+const (
+	postgresImage    = "postgres"
+	postgresImageTag = "14"
+)
+
+// PostgresTestContainerOpt configures the Postgres container started by RunPostgresTestContainer.
+type PostgresTestContainerOpt func(*postgresContainerConfig)
+
+// postgresContainerConfig holds the configuration applied by PostgresTestContainerOpt to a
+// Postgres test container before it's started.
+type postgresContainerConfig struct {
+	tag string
+}
+
+// WithPostgresTag overrides the default Postgres image tag (e.g. "15").
+func WithPostgresTag(tag string) PostgresTestContainerOpt {
+	return func(c *postgresContainerConfig) { c.tag = tag }
+}
+
+// postgresTestContainer implements DatastoreTestContainer for Postgres. It wraps a Postgres
+// container that may be shared across many tests, and hands out an isolated database per test so
+// that tests running against the shared container can't see each other's data.
+type postgresTestContainer struct {
+	container *postgres.PostgresContainer
+	rootCreds string
+	database  string
+
+	// root is true only for the single wrapper stored in the package's shared container
+	// registry. Per-test wrappers returned to callers have root == false, so their Terminate is
+	// a no-op instead of tearing down the container every other test in the package is using;
+	// TerminateSharedContainers is the only supported way to tear down the real container.
+	root bool
+}
+
+// NewPostgresTestContainer returns an implementation of the DatastoreTestContainer interface
+// for Postgres.
+func NewPostgresTestContainer() *postgresTestContainer {
+	return &postgresTestContainer{}
+}
+
+// RunPostgresTestContainer runs a Postgres container (or reuses one already running for this
+// package with the same configuration), provisions an isolated database on it, and returns a
+// bootstrapped implementation of the DatastoreTestContainer interface wired up for the Postgres
+// datastore engine.
+func (p *postgresTestContainer) RunPostgresTestContainer(t testing.TB, opts ...PostgresTestContainerOpt) DatastoreTestContainer {
+	ctx := context.Background()
+
+	cfg := &postgresContainerConfig{tag: postgresImageTag}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	discriminator := fmt.Sprintf("%s:%s", postgresImage, cfg.tag)
+
+	shared, err := getOrCreateSharedContainer("postgres", discriminator, func(startCtx context.Context) (*sharedContainer, error) {
+		c, err := postgres.RunContainer(startCtx,
+			testcontainers.WithImage(fmt.Sprintf("%s:%s", postgresImage, cfg.tag)),
+			postgres.WithDatabase("defaultdb"),
+			postgres.WithUsername("postgres"),
+			postgres.WithPassword("secret"),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to start postgres container: %w", err)
+		}
+
+		host, err := c.Host(startCtx)
+		if err != nil {
+			return nil, err
+		}
+
+		port, err := c.MappedPort(startCtx, "5432/tcp")
+		if err != nil {
+			return nil, err
+		}
+
+		return &sharedContainer{
+			container: &postgresTestContainer{container: c, rootCreds: "postgres:secret", root: true},
+			host:      host,
+			port:      port.Port(),
+		}, nil
+	})
+	require.NoError(t, err, "failed to get or create shared postgres container")
+
+	ulid, err := id.NewString()
+	require.NoError(t, err)
+
+	database := fmt.Sprintf("openfga_test_%s", ulid)
+
+	rootContainer := shared.container.(*postgresTestContainer)
+
+	rootURI := fmt.Sprintf("postgres://postgres:secret@%s:%s/defaultdb?sslmode=disable", shared.host, shared.port)
+
+	db, err := sql.Open("postgres", rootURI)
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.ExecContext(ctx, fmt.Sprintf(`CREATE DATABASE "%s"`, database))
+	require.NoError(t, err, "failed to create isolated postgres test database")
+
+	t.Cleanup(func() {
+		conn, err := sql.Open("postgres", rootURI)
+		if err == nil {
+			_, _ = conn.ExecContext(context.Background(), fmt.Sprintf(`DROP DATABASE IF EXISTS "%s"`, database))
+			_ = conn.Close()
+		}
+	})
+
+	tc := &postgresTestContainer{
+		container: rootContainer.container,
+		rootCreds: "postgres:secret",
+		database:  database,
+	}
+
+	uri, err := tc.ConnectionString(ctx)
+	require.NoError(t, err)
+
+	migrateDB, err := sql.Open("postgres", uri)
+	require.NoError(t, err)
+	defer migrateDB.Close()
+
+	goose.SetLogger(goose.NopLogger())
+
+	err = goose.SetDialect("postgres")
+	require.NoError(t, err)
+
+	goose.SetBaseFS(assets.EmbedMigrations)
+
+	err = goose.Up(migrateDB, assets.PostgresMigrationDir)
+	require.NoError(t, err)
+
+	return tc
+}
+
+// ConnectionString returns the postgres connection uri for this test's isolated database.
+func (p *postgresTestContainer) ConnectionString(ctx context.Context) (string, error) {
+	host, err := p.Host(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	port, err := p.Port(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("postgres://%s@%s:%s/%s?sslmode=disable", p.rootCreds, host, port, p.database), nil
+}
+
+// Host returns the host the underlying postgres container is reachable on.
+func (p *postgresTestContainer) Host(ctx context.Context) (string, error) {
+	return p.container.Host(ctx)
+}
+
+// Port returns the mapped port the underlying postgres container is reachable on.
+func (p *postgresTestContainer) Port(ctx context.Context) (string, error) {
+	port, err := p.container.MappedPort(ctx, "5432/tcp")
+	if err != nil {
+		return "", err
+	}
+
+	return port.Port(), nil
+}
+
+// Terminate stops and removes the underlying postgres container.
+//
+// Per-test containers returned by RunPostgresTestContainer share that container with every other
+// test in the package, so calling Terminate on one of them is a deliberate no-op rather than
+// tearing down the container out from under tests that are still running or haven't run yet. Use
+// TerminateSharedContainers (typically from a package's TestMain) to tear the container down.
+func (p *postgresTestContainer) Terminate(ctx context.Context) error {
+	if !p.root {
+		return nil
+	}
+
+	return p.container.Terminate(ctx)
+}
+
+// GetConnectionURI returns the postgres connection uri for the running postgres test container.
+//
+// Deprecated: use ConnectionString instead.
+func (p *postgresTestContainer) GetConnectionURI() string {
+	uri, err := p.ConnectionString(context.Background())
+	if err != nil {
+		return ""
+	}
+
+	return uri
+}
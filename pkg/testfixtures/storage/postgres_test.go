@@ -0,0 +1,43 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestRunPostgresTestContainer_Parallel demonstrates that two tests requesting differently
+// configured Postgres containers get distinct containers on distinct, dynamically-allocated
+// host ports, so they can run concurrently under `go test -parallel`.
+func TestRunPostgresTestContainer_Parallel(t *testing.T) {
+	t.Parallel()
+
+	ports := make(chan string, 2)
+
+	t.Run("postgres-14", func(t *testing.T) {
+		t.Parallel()
+
+		c := NewPostgresTestContainer().RunPostgresTestContainer(t, WithPostgresTag("14"))
+
+		port, err := c.Port(context.Background())
+		require.NoError(t, err)
+
+		ports <- port
+	})
+
+	t.Run("postgres-15", func(t *testing.T) {
+		t.Parallel()
+
+		c := NewPostgresTestContainer().RunPostgresTestContainer(t, WithPostgresTag("15"))
+
+		port, err := c.Port(context.Background())
+		require.NoError(t, err)
+
+		ports <- port
+	})
+
+	t.Cleanup(func() {
+		assertDistinctPorts(t, "postgres", ports)
+	})
+}
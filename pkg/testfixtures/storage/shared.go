@@ -0,0 +1,146 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"sync"
+	"time"
+
+	"github.com/openfga/openfga/assets"
+)
+
+// containerStartupTimeout bounds how long we'll wait on the Docker daemon to pull an image and
+// start a datastore container, so a wedged daemon fails a test loudly instead of hanging it
+// indefinitely.
+const containerStartupTimeout = 2 * time.Minute
+
+// sharedContainer is a datastore container plus the state needed to hand out isolated,
+// per-test databases on top of it.
+type sharedContainer struct {
+	container DatastoreTestContainer
+	host      string
+	port      string
+}
+
+// sharedContainerSlot reserves a key for a container that is starting (or has started), so that
+// concurrent callers for the *same* key wait for one another, while callers for different keys
+// don't contend on a shared lock for the duration of a (slow) container startup.
+//
+// err is recorded alongside c because sync.Once runs the start function only for the first
+// caller: every later caller for the same key gets back the error that start returned, to fail
+// on, instead of silently inheriting a nil c and panicking on first use.
+type sharedContainerSlot struct {
+	once sync.Once
+	c    *sharedContainer
+	err  error
+}
+
+var (
+	sharedContainersMu sync.Mutex
+	sharedContainers   = map[string]*sharedContainerSlot{}
+)
+
+// migrationDirs maps a datastore engine to the embedded directory of goose migrations that get
+// applied to it.
+var migrationDirs = map[string]string{
+	"mysql":    assets.MySQLMigrationDir,
+	"postgres": assets.PostgresMigrationDir,
+}
+
+// sharedContainerKey identifies a reusable container by engine, a hash of the migrations that
+// get applied to it, and an extra discriminator (typically a summary of the container config,
+// e.g. image/tag/platform) so that two tests requesting differently configured containers for
+// the same engine don't get routed to the same running container.
+func sharedContainerKey(engine, extra string) string {
+	h := sha256.New()
+
+	_, _ = h.Write([]byte(extra))
+
+	dir := migrationDirs[engine]
+
+	_ = fs.WalkDir(assets.EmbedMigrations, dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		b, err := fs.ReadFile(assets.EmbedMigrations, path)
+		if err != nil {
+			return err
+		}
+
+		_, _ = h.Write(b)
+
+		return nil
+	})
+
+	return fmt.Sprintf("%s-%s", engine, hex.EncodeToString(h.Sum(nil))[:12])
+}
+
+// getOrCreateSharedContainer returns the shared container for the given engine, starting it via
+// start (bounded by containerStartupTimeout) if it doesn't already exist. Only the first caller
+// for a given key pays the cost of starting the container; subsequent callers reuse it, similar
+// to a TestMain-scoped singleton but without requiring every package to implement its own
+// TestMain. Callers must check the returned error themselves (e.g. via require.NoError) rather
+// than assume a non-nil *sharedContainer.
+//
+// Reserving a key and starting its container are deliberately split into two locked sections so
+// that the (slow) call to start doesn't hold sharedContainersMu: two callers asking for
+// differently-keyed containers can start them concurrently, and only callers racing for the same
+// key ever wait on one another (via the slot's sync.Once).
+func getOrCreateSharedContainer(engine, extra string, start func(ctx context.Context) (*sharedContainer, error)) (*sharedContainer, error) {
+	key := sharedContainerKey(engine, extra)
+
+	sharedContainersMu.Lock()
+	slot, ok := sharedContainers[key]
+	if !ok {
+		slot = &sharedContainerSlot{}
+		sharedContainers[key] = slot
+	}
+	sharedContainersMu.Unlock()
+
+	slot.once.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), containerStartupTimeout)
+		defer cancel()
+
+		slot.c, slot.err = start(ctx)
+	})
+
+	return slot.c, slot.err
+}
+
+// TerminateSharedContainers terminates every shared datastore container started by this process
+// (across all engines and configurations) and forgets about them. It's meant to be called from a
+// package's TestMain after m.Run() returns, e.g.:
+//
+//	func TestMain(m *testing.M) {
+//		code := m.Run()
+//		_ = storage.TerminateSharedContainers(context.Background())
+//		os.Exit(code)
+//	}
+//
+// This is a courtesy for a fast, clean shutdown; it's not required for correctness, since the
+// session-level Ryuk reaper started by testcontainers-go guarantees these containers are removed
+// even if the process is killed before this runs.
+func TerminateSharedContainers(ctx context.Context) error {
+	sharedContainersMu.Lock()
+	defer sharedContainersMu.Unlock()
+
+	var firstErr error
+
+	for key, slot := range sharedContainers {
+		if slot.c == nil {
+			continue // startup never completed (or is mid-flight); nothing to terminate
+		}
+
+		if err := slot.c.container.Terminate(ctx); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("terminating shared container %q: %w", key, err)
+		}
+
+		delete(sharedContainers, key)
+	}
+
+	return firstErr
+}
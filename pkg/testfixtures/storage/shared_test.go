@@ -0,0 +1,22 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// assertDistinctPorts drains ports (closing it first) and fails t if any two containers reported
+// the same host port, which would mean they collided instead of running concurrently. It's shared
+// by every engine's TestRun*TestContainer_Parallel test via t.Cleanup.
+func assertDistinctPorts(t *testing.T, engine string, ports chan string) {
+	t.Helper()
+
+	close(ports)
+
+	seen := map[string]bool{}
+	for port := range ports {
+		require.False(t, seen[port], "expected each concurrently-running %s container to have a distinct host port, got a collision on %q", engine, port)
+		seen[port] = true
+	}
+}